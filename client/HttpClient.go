@@ -2,10 +2,12 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -18,6 +20,10 @@ type EventsClient struct {
 	Debug          bool
 	EventSize      int
 	BlockSize      int
+	RateLimiter    *RateLimiter // 限流器，为nil表示不限流
+	Concurrency    int          // 区块回填时的并发worker数，<=1表示单线程顺序拉取
+	CursorStore    CursorStore  // 游标存储，为nil表示不持久化游标（每次都从req.FromBlock开始）
+	SubscriptionID string       // 游标存储中用于标识本次订阅的key，与CursorStore配合使用
 }
 
 // Config 客户端配置
@@ -28,6 +34,10 @@ type Config struct {
 	Debug          bool          // 调试模式
 	EventSize      int           // 批量获取事件数量
 	BlockSize      int           // 批量获取事件块大小
+	RateLimiter    *RateLimiter  // 限流器（令牌桶，每秒请求数+突发量），为nil表示不限流
+	Concurrency    int           // 区块回填时并行拉取的worker数，<=1表示退化为原来的单线程轮询
+	CursorStore    CursorStore   // 游标存储，为nil表示不持久化游标
+	SubscriptionID string        // 游标存储中用于标识本次订阅的key
 }
 
 // NewEventsClient 创建新的客户端实例
@@ -59,6 +69,10 @@ func NewEventsClient(config *Config) *EventsClient {
 		Debug:          config.Debug,
 		EventSize:      config.EventSize,
 		BlockSize:      config.BlockSize,
+		RateLimiter:    config.RateLimiter,
+		Concurrency:    config.Concurrency,
+		CursorStore:    config.CursorStore,
+		SubscriptionID: config.SubscriptionID,
 	}
 }
 
@@ -140,8 +154,22 @@ type Page struct {
 	Data  []*Event `json:"data"`  // 数据
 }
 
-// SubscribeEvents 模拟订阅事件
-func (c *EventsClient) SubscribeEvents(req *FlowEventsRequest, dataChannel chan *EventData, committedChannel chan interface{}) error {
+// SubscribeEvents 模拟订阅事件。当Concurrency>1时，会将[FromBlock, latest]窗口
+// 分片后并发拉取，再按分片顺序重排后推送，吞吐量更高；否则退化为单线程轮询。
+// 若配置了CursorStore，会优先从已保存的游标续传，而不是req.FromBlock。
+// ctx被取消时，轮询/回填循环会尽快停止，限流器等待中的请求也会随之中止
+func (c *EventsClient) SubscribeEvents(ctx context.Context, req *FlowEventsRequest, dataChannel chan *EventData, committedChannel chan interface{}) error {
+	resumed, err := c.resumeFromBlock(req.FromBlock)
+	if err != nil {
+		return err
+	}
+	req = &FlowEventsRequest{FromBlock: resumed, Address: req.Address, EventNames: req.EventNames}
+
+	if c.Concurrency > 1 {
+		go c.subscribeEventsConcurrent(ctx, req, dataChannel, committedChannel)
+		return nil
+	}
+
 	innerReq := &HttpEventsRequest{
 		FromBlock:  req.FromBlock,
 		ToBlock:    req.FromBlock + c.BlockSize,
@@ -155,17 +183,234 @@ func (c *EventsClient) SubscribeEvents(req *FlowEventsRequest, dataChannel chan
 		defer timer.Stop()
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case <-timer.C:
-				c.CycleGetEvents(innerReq, dataChannel, committedChannel, timer)
+				c.CycleGetEvents(ctx, innerReq, dataChannel, committedChannel, timer)
 			}
 		}
 	}(innerReq, dataChannel, committedChannel)
 	return nil
 }
 
-func (c *EventsClient) CycleGetEvents(innerReq *HttpEventsRequest, dataChannel chan *EventData, committedChannel chan interface{}, timer *time.Timer) {
+// subscribeEventsConcurrent 以c.Concurrency个worker并发拉取[fromBlock, latest]区间，
+// 通过重排序缓冲区保证推送到dataChannel的顺序仍按区块号单调递增
+func (c *EventsClient) subscribeEventsConcurrent(ctx context.Context, req *FlowEventsRequest, dataChannel chan *EventData, committedChannel chan interface{}) {
+	fromBlock := req.FromBlock
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		latestBlockNumber, err := c.GetLatestBlockNumber(ctx)
+		if err != nil {
+			fmt.Printf("get latest block number failed: %s\n", err)
+			if sleepOrDone(ctx, c.RequestPeriod) {
+				return
+			}
+			continue
+		}
+		latest := int(latestBlockNumber)
+		if fromBlock > latest {
+			if sleepOrDone(ctx, c.RequestPeriod) {
+				return
+			}
+			continue
+		}
+
+		nextFromBlock, results, err := c.fetchEventShards(ctx, req, fromBlock, latest)
+		if err != nil {
+			fmt.Printf("fetch event shards failed: %s\n", err)
+			if sleepOrDone(ctx, c.RequestPeriod) {
+				return
+			}
+			continue
+		}
+		for _, eventData := range results {
+			select {
+			case dataChannel <- eventData:
+			case <-ctx.Done():
+				return
+			}
+			// 如果设置了commit channel，会等待消费者消费完成，才推送下一个分片
+			if committedChannel != nil {
+				select {
+				case <-committedChannel:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// 消费确认完成后再推进游标，保证至少一次投递语义
+			c.saveCursor(eventData.MetaData.ScanLatestBlockNumber, eventData.MetaData.ScanLatestBlockCompleted)
+		}
+		fromBlock = nextFromBlock
+		if sleepOrDone(ctx, c.RequestPeriod) {
+			return
+		}
+	}
+}
+
+// sleepOrDone 休眠d或直到ctx被取消，返回true表示ctx已取消，调用方应立即退出
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// eventShardRange 描述一个待拉取的区块分片
+type eventShardRange struct {
+	index     int
+	fromBlock int
+	toBlock   int
+}
+
+// fetchEventShards 将[fromBlock, latest]按c.BlockSize切分成若干分片，用最多
+// c.Concurrency个worker并发拉取，再按分片下标重排后返回，下一轮的起始区块号一并返回
+func (c *EventsClient) fetchEventShards(ctx context.Context, req *FlowEventsRequest, fromBlock, latest int) (int, []*EventData, error) {
+	var shards []eventShardRange
+	for from, idx := fromBlock, 0; from <= latest; from, idx = from+c.BlockSize+1, idx+1 {
+		to := from + c.BlockSize
+		if to > latest {
+			to = latest
+		}
+		shards = append(shards, eventShardRange{index: idx, fromBlock: from, toBlock: to})
+	}
+	if len(shards) == 0 {
+		return fromBlock, nil, nil
+	}
+
+	type shardResult struct {
+		index     int
+		eventData *EventData
+		err       error
+	}
+
+	shardCh := make(chan eventShardRange)
+	resultCh := make(chan shardResult, len(shards))
+
+	workerCount := c.Concurrency
+	if workerCount > len(shards) {
+		workerCount = len(shards)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workerCount; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range shardCh {
+				if ctx.Err() != nil {
+					resultCh <- shardResult{index: shard.index, err: ctx.Err()}
+					continue
+				}
+				eventData, err := c.fetchEventShard(ctx, req, shard)
+				resultCh <- shardResult{index: shard.index, eventData: eventData, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, shard := range shards {
+			shardCh <- shard
+		}
+		close(shardCh)
+	}()
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	// 重排序缓冲区：worker完成顺序不固定，按分片下标收集后再整体排序返回
+	reorderBuffer := make(map[int]*EventData, len(shards))
+	var firstErr error
+	for res := range resultCh {
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			continue
+		}
+		reorderBuffer[res.index] = res.eventData
+	}
+	if firstErr != nil {
+		return fromBlock, nil, firstErr
+	}
+
+	ordered := make([]*EventData, 0, len(shards))
+	for i := range shards {
+		ordered = append(ordered, reorderBuffer[i])
+	}
+	return shards[len(shards)-1].toBlock + 1, ordered, nil
+}
+
+// fetchEventShard 翻页拉取单个分片范围内的全部事件
+func (c *EventsClient) fetchEventShard(ctx context.Context, req *FlowEventsRequest, shard eventShardRange) (*EventData, error) {
+	innerReq := &HttpEventsRequest{
+		FromBlock:  shard.fromBlock,
+		ToBlock:    shard.toBlock,
+		Address:    req.Address,
+		EventNames: req.EventNames,
+		PageNumber: 1,
+		PageSize:   c.EventSize,
+	}
+	var events []*Event
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		response, err := c.GetEvents(ctx, innerReq)
+		if err != nil {
+			return nil, err
+		}
+		if response.Data != nil {
+			events = append(events, response.Data.Data...)
+		}
+		if response.Data == nil || len(response.Data.Data) < c.EventSize || (response.Data.Page*response.Data.Size == response.Data.Total) {
+			break
+		}
+		innerReq.PageNumber++
+	}
+	return &EventData{
+		Events: events,
+		MetaData: &MetaData{
+			ScanLatestBlockNumber:    shard.toBlock,
+			ScanLatestBlockCompleted: true,
+		},
+	}, nil
+}
+
+// resumeFromBlock 若配置了CursorStore和SubscriptionID，返回已保存的游标；
+// 否则原样返回调用方传入的fromBlock
+func (c *EventsClient) resumeFromBlock(fromBlock int) (int, error) {
+	if c.CursorStore == nil || c.SubscriptionID == "" {
+		return fromBlock, nil
+	}
+	stored, err := c.CursorStore.Load(c.SubscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("load cursor failed: %w", err)
+	}
+	if stored > 0 {
+		return stored, nil
+	}
+	return fromBlock, nil
+}
+
+// saveCursor 将游标保存到CursorStore，未配置CursorStore时为空操作
+func (c *EventsClient) saveCursor(block int, completed bool) {
+	if c.CursorStore == nil || c.SubscriptionID == "" {
+		return
+	}
+	if err := c.CursorStore.Save(c.SubscriptionID, block, completed); err != nil {
+		fmt.Printf("save cursor failed: %s\n", err)
+	}
+}
+
+func (c *EventsClient) CycleGetEvents(ctx context.Context, innerReq *HttpEventsRequest, dataChannel chan *EventData, committedChannel chan interface{}, timer *time.Timer) {
 	defer timer.Reset(c.RequestPeriod)
-	latestBlockNumber, err := c.GetLatestBlockNumber()
+	latestBlockNumber, err := c.GetLatestBlockNumber(ctx)
 	if err != nil {
 		fmt.Printf("get latest block number failed: %s\n", err)
 		return
@@ -175,7 +420,7 @@ func (c *EventsClient) CycleGetEvents(innerReq *HttpEventsRequest, dataChannel c
 		return
 	}
 	// 重新构造请求参数
-	response, err := c.GetEvents(innerReq)
+	response, err := c.GetEvents(ctx, innerReq)
 	if err != nil {
 		fmt.Printf("get events failed: %s\n", err)
 		return
@@ -201,14 +446,24 @@ func (c *EventsClient) CycleGetEvents(innerReq *HttpEventsRequest, dataChannel c
 		innerReq.Reset(innerReq.FromBlock, innerReq.ToBlock, innerReq.PageNumber+1, c.EventSize)
 	}
 	// 发送数据
-	dataChannel <- eventData
+	select {
+	case dataChannel <- eventData:
+	case <-ctx.Done():
+		return
+	}
 	// 如果设置了commit channel，会等待消费者消费完成，才查询后续的数据
 	if committedChannel != nil {
-		<-committedChannel
+		select {
+		case <-committedChannel:
+		case <-ctx.Done():
+			return
+		}
 	}
+	// 消费确认完成后再推进游标，保证至少一次投递语义
+	c.saveCursor(metaData.ScanLatestBlockNumber, metaData.ScanLatestBlockCompleted)
 }
 
-func (c *EventsClient) GetLatestBlockNumber() (uint64, error) {
+func (c *EventsClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
 	url := fmt.Sprintf("%s/api/v1/event/latestBlockNumber", c.BaseURL)
 	var lastErr error = nil
 	// 最多重试3次
@@ -217,10 +472,17 @@ func (c *EventsClient) GetLatestBlockNumber() (uint64, error) {
 		if attempt > 0 {
 			// 指数退避：第一次重试等1秒，第二次等2秒
 			waitTime := time.Duration(attempt) * time.Second
-			time.Sleep(waitTime)
+			if sleepOrDone(ctx, waitTime) {
+				return 0, ctx.Err()
+			}
+		}
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return 0, fmt.Errorf("rate limiter wait failed: %w", err)
+			}
 		}
 		// 创建HTTP请求
-		httpReq, err := http.NewRequest("GET", url, nil)
+		httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			lastErr = fmt.Errorf("create http request failed: %w", err)
 			continue // 继续重试
@@ -263,7 +525,7 @@ func (c *EventsClient) GetLatestBlockNumber() (uint64, error) {
 	return 0, lastErr
 }
 
-func (c *EventsClient) GetEvents(req *HttpEventsRequest) (*PageResponse, error) {
+func (c *EventsClient) GetEvents(ctx context.Context, req *HttpEventsRequest) (*PageResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/event/list", c.BaseURL)
 	var lastErr error = nil
 	// 最多重试3次
@@ -276,10 +538,17 @@ func (c *EventsClient) GetEvents(req *HttpEventsRequest) (*PageResponse, error)
 		if attempt > 0 {
 			// 指数退避：第一次重试等1秒，第二次等2秒
 			waitTime := time.Duration(attempt) * time.Second
-			time.Sleep(waitTime)
+			if sleepOrDone(ctx, waitTime) {
+				return nil, ctx.Err()
+			}
+		}
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+			}
 		}
 		// 创建HTTP请求
-		httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			lastErr = fmt.Errorf("create http request failed: %w", err)
 			continue // 继续重试
@@ -6,26 +6,42 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 )
 
-// StreamClient 流式客户端
+// defaultSubscriptionID 在调用方未指定SubscriptionID时，用作初始订阅的标识
+const defaultSubscriptionID = "default"
+
+// streamSubscription 记录单个过滤订阅的当前请求参数和续传位置
+type streamSubscription struct {
+	request       WSRequest
+	lastSeenBlock int // 最后一次收到的events/new_event消息中的区块号，用于重连/更新续传
+}
+
+// StreamClient 流式客户端，单条WebSocket连接上可同时维护多个过滤订阅
 type StreamClient struct {
-	wsClient *WSClient
-	buffer   chan *WSMessage
-	ctx      context.Context
-	cancel   context.CancelFunc
+	wsClient      *WSClient
+	buffer        chan *WSMessage
+	ctx           context.Context
+	cancel        context.CancelFunc
+	mu            sync.Mutex
+	subscriptions map[string]*streamSubscription
 }
 
 // StreamConfig 流式配置
 type StreamConfig struct {
-	URL           string
-	Request       WSRequest
-	BufferSize    int
-	MaxRetries    int
-	RetryInterval time.Duration
-	OnEvents      func([]Event, int, int) // 事件回调：事件列表，页码，总数
-	OnError       func(error)
+	URL            string
+	Request        WSRequest
+	BufferSize     int
+	MaxRetries     int
+	RetryInterval  time.Duration
+	OnEvents       func([]Event, int, int, string) error // 事件回调：事件列表，页码，总数，所属订阅ID；返回nil后游标才会被保存
+	OnError        func(error)
+	OnReconnect    func(attempt int, err error) // 重连回调：err非nil表示本次尝试失败
+	OnResume       func(fromBlock int)          // 重连成功、重新发起订阅后触发，fromBlock为续传起点
+	CursorStore    CursorStore                  // 游标存储，为nil表示不持久化游标
+	SubscriptionID string                       // 初始订阅的ID，同时也是CursorStore的key；留空则使用"default"
 }
 
 // NewStreamClient 创建流式客户端
@@ -33,14 +49,43 @@ func NewStreamClient(config StreamConfig) *StreamClient {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &StreamClient{
-		buffer: make(chan *WSMessage, config.BufferSize),
-		ctx:    ctx,
-		cancel: cancel,
+		buffer:        make(chan *WSMessage, config.BufferSize),
+		ctx:           ctx,
+		cancel:        cancel,
+		subscriptions: make(map[string]*streamSubscription),
 	}
 }
 
-// Start 开始流式监听
+// Start 开始流式监听。若配置了CursorStore，会优先从已保存的游标续传，
+// 而不是config.Request.FromBlock
 func (sc *StreamClient) Start(config StreamConfig) error {
+	subID := config.SubscriptionID
+	if subID == "" {
+		subID = defaultSubscriptionID
+	}
+	config.Request.SubscriptionID = subID
+	if config.Request.Op == "" {
+		config.Request.Op = WSOpSubscribe
+	}
+
+	if config.CursorStore != nil {
+		stored, err := config.CursorStore.Load(subID)
+		if err != nil {
+			return fmt.Errorf("load cursor failed: %w", err)
+		}
+		if stored > 0 {
+			config.Request.FromBlock = stored
+		}
+	}
+
+	sc.mu.Lock()
+	sc.subscriptions[subID] = &streamSubscription{
+		request:       config.Request,
+		lastSeenBlock: config.Request.FromBlock - 1,
+	}
+	sc.mu.Unlock()
+
+	var wsClient *WSClient
 	// 创建WebSocket客户端
 	wsConfig := WSConfig{
 		URL: config.URL,
@@ -51,9 +96,39 @@ func (sc *StreamClient) Start(config StreamConfig) error {
 		OnClose: func() {
 			log.Println("WebSocket connection closed")
 		},
+		OnReconnect: func(attempt int, err error) {
+			if err != nil {
+				if config.OnReconnect != nil {
+					config.OnReconnect(attempt, err)
+				}
+				return
+			}
+			// 重连成功：所有活跃订阅都从各自最后收到的区块号之后续传，避免事件丢失或重复
+			sc.mu.Lock()
+			subs := make([]*streamSubscription, 0, len(sc.subscriptions))
+			for _, sub := range sc.subscriptions {
+				subs = append(subs, sub)
+			}
+			sc.mu.Unlock()
+
+			for _, sub := range subs {
+				resumeReq := sub.request
+				resumeReq.Op = WSOpSubscribe
+				resumeReq.FromBlock = sub.lastSeenBlock + 1
+				if sendErr := wsClient.SendRequest(resumeReq); sendErr != nil {
+					if config.OnError != nil {
+						config.OnError(fmt.Errorf("resume request failed: %w", sendErr))
+					}
+					continue
+				}
+				if config.OnResume != nil {
+					config.OnResume(resumeReq.FromBlock)
+				}
+			}
+		},
 	}
 
-	wsClient := NewWSClient(wsConfig)
+	wsClient = NewWSClient(wsConfig)
 
 	// 连接
 	if err := wsClient.Connect(); err != nil {
@@ -68,18 +143,77 @@ func (sc *StreamClient) Start(config StreamConfig) error {
 	}
 
 	// 处理消息
-	go sc.processMessages(config)
+	go sc.processMessages(config, subID)
 
 	return nil
 }
 
-// processMessages 处理消息
-func (sc *StreamClient) processMessages(config StreamConfig) {
+// Subscribe 在已建立的连接上新增一个过滤订阅，与初始订阅共享同一条WebSocket连接
+func (sc *StreamClient) Subscribe(subscriptionID string, req WSRequest) error {
+	req.SubscriptionID = subscriptionID
+	req.Op = WSOpSubscribe
+
+	sc.mu.Lock()
+	sc.subscriptions[subscriptionID] = &streamSubscription{
+		request:       req,
+		lastSeenBlock: req.FromBlock - 1,
+	}
+	sc.mu.Unlock()
+
+	return sc.wsClient.SendRequest(req)
+}
+
+// Update 更新已存在订阅的过滤条件；续传位置延续之前已追踪的区块号，而不是req.FromBlock
+func (sc *StreamClient) Update(subscriptionID string, req WSRequest) error {
+	req.SubscriptionID = subscriptionID
+	req.Op = WSOpUpdate
+
+	sc.mu.Lock()
+	if existing, ok := sc.subscriptions[subscriptionID]; ok {
+		req.FromBlock = existing.lastSeenBlock + 1
+		existing.request = req
+	} else {
+		sc.subscriptions[subscriptionID] = &streamSubscription{request: req, lastSeenBlock: req.FromBlock - 1}
+	}
+	sc.mu.Unlock()
+
+	return sc.wsClient.SendRequest(req)
+}
+
+// Unsubscribe 取消一个订阅
+func (sc *StreamClient) Unsubscribe(subscriptionID string) error {
+	sc.mu.Lock()
+	delete(sc.subscriptions, subscriptionID)
+	sc.mu.Unlock()
+
+	return sc.wsClient.SendRequest(WSRequest{SubscriptionID: subscriptionID, Op: WSOpUnsubscribe})
+}
+
+// Subscriptions 返回当前连接上所有活跃订阅的快照，键为订阅ID
+func (sc *StreamClient) Subscriptions() map[string]WSRequest {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	result := make(map[string]WSRequest, len(sc.subscriptions))
+	for id, sub := range sc.subscriptions {
+		result[id] = sub.request
+	}
+	return result
+}
+
+// processMessages 处理消息，按msg.SubscriptionID路由到对应订阅；
+// 服务端未回填SubscriptionID时，归属到defaultSubID（初始订阅）
+func (sc *StreamClient) processMessages(config StreamConfig, defaultSubID string) {
 	for {
 		select {
 		case <-sc.ctx.Done():
 			return
 		case msg := <-sc.buffer:
+			subID := msg.SubscriptionID
+			if subID == "" {
+				subID = defaultSubID
+			}
+
 			switch msg.Type {
 			case "events":
 				var events []Event
@@ -90,9 +224,7 @@ func (sc *StreamClient) processMessages(config StreamConfig) {
 					continue
 				}
 
-				if config.OnEvents != nil {
-					config.OnEvents(events, msg.Page, msg.Total)
-				}
+				sc.dispatchEvents(config, subID, events, msg.Page, msg.Total)
 
 			case "new_event":
 				var event Event
@@ -103,9 +235,7 @@ func (sc *StreamClient) processMessages(config StreamConfig) {
 					continue
 				}
 
-				if config.OnEvents != nil {
-					config.OnEvents([]Event{event}, 0, 0)
-				}
+				sc.dispatchEvents(config, subID, []Event{event}, 0, 0)
 
 			case "error":
 				if config.OnError != nil {
@@ -120,6 +250,69 @@ func (sc *StreamClient) processMessages(config StreamConfig) {
 	}
 }
 
+// dispatchEvents 调用OnEvents回调；只有回调返回nil后，这批事件才会被计入
+// lastSeenBlock（重连/更新续传的起点）并保存游标。如果回调返回错误，或者
+// 处理过程中发生重连，lastSeenBlock都不会提前推进，保证重连续传和磁盘游标
+// 遵循同一套至少一次（at-least-once）语义——未确认处理成功的事件，下次会
+// 被重新投递，而不是被悄悄跳过
+func (sc *StreamClient) dispatchEvents(config StreamConfig, subscriptionID string, events []Event, page, total int) {
+	if config.OnEvents == nil {
+		return
+	}
+	if err := config.OnEvents(events, page, total, subscriptionID); err != nil {
+		if config.OnError != nil {
+			config.OnError(fmt.Errorf("handle events failed: %w", err))
+		}
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	sc.trackLastSeenBlock(subscriptionID, events)
+
+	if config.CursorStore == nil {
+		return
+	}
+
+	sc.mu.Lock()
+	sub, ok := sc.subscriptions[subscriptionID]
+	var block int
+	if ok {
+		block = sub.lastSeenBlock
+	}
+	sc.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if err := config.CursorStore.Save(subscriptionID, block, false); err != nil {
+		if config.OnError != nil {
+			config.OnError(fmt.Errorf("save cursor failed: %w", err))
+		}
+	}
+}
+
+// trackLastSeenBlock 记录订阅已收到事件中的最大区块号，供重连/更新续传使用
+func (sc *StreamClient) trackLastSeenBlock(subscriptionID string, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sub, ok := sc.subscriptions[subscriptionID]
+	if !ok {
+		sub = &streamSubscription{}
+		sc.subscriptions[subscriptionID] = sub
+	}
+	for _, event := range events {
+		if int(event.BlockNumber) > sub.lastSeenBlock {
+			sub.lastSeenBlock = int(event.BlockNumber)
+		}
+	}
+}
+
 // Stop 停止流式监听
 func (sc *StreamClient) Stop() {
 	sc.cancel()
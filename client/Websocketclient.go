@@ -2,65 +2,139 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// 重连退避参数：初始2秒，指数翻倍，最高封顶64秒
+const (
+	initialReconnectBackoff = 2 * time.Second
+	maxReconnectBackoff     = 64 * time.Second
+)
+
+// 心跳默认参数，沿用gorilla/websocket推荐的pong-deadline模式
+const (
+	defaultPongWait       = 60 * time.Second
+	defaultWriteWait      = 10 * time.Second
+	defaultMaxMessageSize = 512 * 1024
+)
+
+// ErrHeartbeatTimeout 表示在pongWait内未收到任何pong/消息，连接被判定为空闲
+var ErrHeartbeatTimeout = errors.New("websocket: heartbeat timeout, no pong received")
+
+// connSession 标识一次具体的底层连接。readPump/writePump退出、或这条连接被
+// 新连接取代时，done会被关闭，通知同一代的另一方尽快停止，避免重连后出现
+// 两代goroutine同时向各自以为"当前"的conn写入的情况
+type connSession struct {
+	conn *websocket.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+func newConnSession(conn *websocket.Conn) *connSession {
+	return &connSession{conn: conn, done: make(chan struct{})}
+}
+
+// close 关闭done，可重复调用
+func (s *connSession) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
 // WSClient WebSocket客户端
 type WSClient struct {
-	conn        *websocket.Conn
-	url         string
-	onMessage   func(*WSMessage)
-	onError     func(error)
-	onClose     func()
-	pingPeriod  time.Duration
-	done        chan struct{}
-	mu          sync.RWMutex
-	isConnected bool
+	conn           *websocket.Conn
+	session        *connSession // 当前连接所在的一代，用于让readPump/writePump结对退出
+	url            string
+	onMessage      func(*WSMessage)
+	onError        func(error)
+	onClose        func()
+	onReconnect    func(attempt int, err error) // 重连回调：err非nil表示本次尝试失败，err为nil表示重连成功
+	pingPeriod     time.Duration
+	pongWait       time.Duration
+	writeWait      time.Duration
+	maxMessageSize int64
+	done           chan struct{}
+	mu             sync.RWMutex
+	isConnected    bool
+	stopped        bool // 标记Close()已被显式调用，此后不再自动重连
 }
 
 // WSConfig WebSocket配置
 type WSConfig struct {
-	URL        string           // WebSocket URL
-	OnMessage  func(*WSMessage) // 消息回调
-	OnError    func(error)      // 错误回调
-	OnClose    func()           // 关闭回调
-	PingPeriod time.Duration    // Ping间隔
-	BufferSize int              // 缓冲区大小
+	URL            string                       // WebSocket URL
+	OnMessage      func(*WSMessage)             // 消息回调
+	OnError        func(error)                  // 错误回调
+	OnClose        func()                       // 关闭回调（仅在Close()被显式调用后触发）
+	OnReconnect    func(attempt int, err error) // 重连回调
+	PingPeriod     time.Duration                // Ping间隔，默认为(PongWait*9)/10
+	PongWait       time.Duration                // 等待pong的超时时间，默认60秒
+	WriteWait      time.Duration                // 写超时时间，默认10秒
+	MaxMessageSize int64                        // 单条消息最大字节数，默认512KB
+	BufferSize     int                          // 缓冲区大小
 }
 
 // WSMessage WebSocket消息结构
 type WSMessage struct {
-	Type    string          `json:"type"`    // "events", "error", "info", "heartbeat", "new_event"
-	Message string          `json:"message"` // 消息内容
-	Data    json.RawMessage `json:"data"`    // 数据（原始JSON）
-	Page    int             `json:"page"`    // 当前页码
-	Total   int             `json:"total"`   // 总数据量
+	Type           string          `json:"type"`                     // "events", "error", "info", "heartbeat", "new_event"
+	Message        string          `json:"message"`                  // 消息内容
+	Data           json.RawMessage `json:"data"`                     // 数据（原始JSON）
+	Page           int             `json:"page"`                     // 当前页码
+	Total          int             `json:"total"`                    // 总数据量
+	SubscriptionID string          `json:"subscriptionId,omitempty"` // 该消息所属的订阅ID，用于单连接多路复用
 }
 
+// WSRequest.Op取值：在一条连接上新增/更新/取消一个过滤订阅
+const (
+	WSOpSubscribe   = "subscribe"
+	WSOpUpdate      = "update"
+	WSOpUnsubscribe = "unsubscribe"
+)
+
 // WSRequest WebSocket请求
 type WSRequest struct {
-	FromBlock int    `json:"fromBlock,omitempty"`
-	ToBlock   int    `json:"toBlock,omitempty"`
-	Address   string `json:"address,omitempty"`
+	Op             string     `json:"op,omitempty"`             // "subscribe"/"update"/"unsubscribe"，不填默认为subscribe
+	SubscriptionID string     `json:"subscriptionId,omitempty"` // 订阅ID，服务端据此在单连接上多路复用多个过滤条件
+	FromBlock      int        `json:"fromBlock,omitempty"`
+	ToBlock        int        `json:"toBlock,omitempty"`
+	Address        string     `json:"address,omitempty"`
+	EventNames     []string   `json:"eventNames,omitempty"`     // 按事件名过滤
+	Topics         [][]string `json:"topics,omitempty"`         // 按索引topic过滤，每个位置内部为析取(OR)，位置之间为合取(AND)，语义对齐以太坊日志过滤器
+	UserAddresses  []string   `json:"userAddresses,omitempty"`  // 按用户地址过滤
+	IncludeRemoved bool       `json:"includeRemoved,omitempty"` // 是否包含因链重组被标记为removed的事件
 }
 
 // NewWSClient 创建WebSocket客户端
 func NewWSClient(config WSConfig) *WSClient {
+	if config.PongWait == 0 {
+		config.PongWait = defaultPongWait
+	}
+	if config.WriteWait == 0 {
+		config.WriteWait = defaultWriteWait
+	}
+	if config.MaxMessageSize == 0 {
+		config.MaxMessageSize = defaultMaxMessageSize
+	}
 	if config.PingPeriod == 0 {
-		config.PingPeriod = 30 * time.Second
+		config.PingPeriod = (config.PongWait * 9) / 10
 	}
 
 	return &WSClient{
-		url:        config.URL,
-		onMessage:  config.OnMessage,
-		onError:    config.OnError,
-		onClose:    config.OnClose,
-		pingPeriod: config.PingPeriod,
-		done:       make(chan struct{}),
+		url:            config.URL,
+		onMessage:      config.OnMessage,
+		onError:        config.OnError,
+		onClose:        config.OnClose,
+		onReconnect:    config.OnReconnect,
+		pingPeriod:     config.PingPeriod,
+		pongWait:       config.PongWait,
+		writeWait:      config.WriteWait,
+		maxMessageSize: config.MaxMessageSize,
+		done:           make(chan struct{}),
 	}
 }
 
@@ -77,14 +151,32 @@ func (c *WSClient) Connect() error {
 		return fmt.Errorf("connect to websocket failed: %w", err)
 	}
 
+	// 心跳：收到pong即延长读超时，读超时触发后由readPump判定为ErrHeartbeatTimeout
+	conn.SetReadLimit(c.maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	})
+
+	session := newConnSession(conn)
+
 	c.mu.Lock()
+	// 正常情况下上一代的readPump会在自己退出前关闭session，这里只是兜底：
+	// 如果Connect被重复调用、上一代连接还没退出，也要立刻关闭它的conn并让它的
+	// readPump/writePump停下来，避免旧连接的fd和goroutine泄漏，也避免两代
+	// goroutine同时持有"当前连接"的引用并发写入
+	if c.session != nil {
+		_ = c.session.conn.Close()
+		c.session.close()
+	}
 	c.conn = conn
+	c.session = session
 	c.isConnected = true
 	c.mu.Unlock()
 
-	// 启动读写协程
-	go c.readPump()
-	go c.writePump()
+	// 启动读写协程，两者共享同一个session，生命周期绑定在一起
+	go c.readPump(session)
+	go c.writePump(session)
 
 	return nil
 }
@@ -103,12 +195,25 @@ func (c *WSClient) SendRequest(req WSRequest) error {
 		return fmt.Errorf("marshal request failed: %w", err)
 	}
 
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
 	return c.conn.WriteMessage(websocket.TextMessage, data)
 }
 
-// Close 关闭连接
+// Close 关闭连接，之后不会再自动重连
 func (c *WSClient) Close() error {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return nil
+	}
+	c.stopped = true
+	session := c.session
+	c.mu.Unlock()
+
 	close(c.done)
+	if session != nil {
+		session.close()
+	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -129,19 +234,30 @@ func (c *WSClient) IsConnected() bool {
 	return c.isConnected
 }
 
-// readPump 读取消息
-func (c *WSClient) readPump() {
+// readPump 读取消息。连接出错或收到关闭帧时，只要Close()未被显式调用，就会触发自动重连。
+// session绑定了这次Connect()建立的具体连接：readPump退出前会关闭session，让同一代的
+// writePump（它只认这个session，不会去读可能已经指向下一代连接的c.conn）尽快停止，
+// 不再等待自己的ping周期才发现连接已经不属于自己
+func (c *WSClient) readPump(session *connSession) {
+	conn := session.conn
 	defer func() {
+		session.close()
+
 		c.mu.Lock()
-		if c.conn != nil {
-			_ = c.conn.Close()
+		if c.conn == conn {
+			_ = conn.Close()
+			c.isConnected = false
+			c.conn = nil
 		}
-		c.isConnected = false
-		c.conn = nil
+		stopped := c.stopped
 		c.mu.Unlock()
 
-		if c.onClose != nil {
-			c.onClose()
+		if stopped {
+			if c.onClose != nil {
+				c.onClose()
+			}
+		} else {
+			go c.reconnect()
 		}
 	}()
 
@@ -149,43 +265,47 @@ func (c *WSClient) readPump() {
 		select {
 		case <-c.done:
 			return
+		case <-session.done:
+			return
 		default:
-			c.mu.RLock()
-			conn := c.conn
-			c.mu.RUnlock()
-
-			if conn == nil {
-				return
-			}
+		}
 
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					if c.onError != nil {
-						c.onError(err)
-					}
-				}
-				return
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			reportErr := err
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				// pongWait内没有收到任何pong/消息，视为空闲连接
+				reportErr = ErrHeartbeatTimeout
 			}
-
-			// 解析消息
-			var wsMsg WSMessage
-			if err := json.Unmarshal(message, &wsMsg); err != nil {
+			if reportErr == ErrHeartbeatTimeout || websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				if c.onError != nil {
-					c.onError(fmt.Errorf("parse message failed: %w", err))
+					c.onError(reportErr)
 				}
-				continue
 			}
+			return
+		}
 
-			if c.onMessage != nil {
-				c.onMessage(&wsMsg)
+		// 解析消息
+		var wsMsg WSMessage
+		if err := json.Unmarshal(message, &wsMsg); err != nil {
+			if c.onError != nil {
+				c.onError(fmt.Errorf("parse message failed: %w", err))
 			}
+			continue
+		}
+
+		if c.onMessage != nil {
+			c.onMessage(&wsMsg)
 		}
 	}
 }
 
-// writePump 写入消息和保持心跳
-func (c *WSClient) writePump() {
+// writePump 写入消息和保持心跳。只针对session.conn这一条具体连接发送ping，
+// 一旦被session.done通知（自身readPump退出，或被新的Connect()取代），立刻停止，
+// 不会像共享done那样要等到下一次ping周期才发现自己该退出了
+func (c *WSClient) writePump(session *connSession) {
+	conn := session.conn
 	ticker := time.NewTicker(c.pingPeriod)
 	defer ticker.Stop()
 
@@ -193,16 +313,11 @@ func (c *WSClient) writePump() {
 		select {
 		case <-c.done:
 			return
+		case <-session.done:
+			return
 		case <-ticker.C:
-			c.mu.RLock()
-			conn := c.conn
-			c.mu.RUnlock()
-
-			if conn == nil {
-				return
-			}
-
 			// 发送Ping
+			_ = conn.SetWriteDeadline(time.Now().Add(c.writeWait))
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				if c.onError != nil {
 					c.onError(err)
@@ -212,3 +327,49 @@ func (c *WSClient) writePump() {
 		}
 	}
 }
+
+// reconnect 以指数退避（初始2秒，上限64秒，带抖动）不断尝试重新拨号，
+// 直到成功或Close()被显式调用为止
+func (c *WSClient) reconnect() {
+	backoff := initialReconnectBackoff
+	attempt := 0
+	for {
+		c.mu.RLock()
+		stopped := c.stopped
+		c.mu.RUnlock()
+		if stopped {
+			return
+		}
+
+		attempt++
+		select {
+		case <-c.done:
+			return
+		case <-time.After(backoff + jitter(backoff)):
+		}
+
+		if err := c.Connect(); err != nil {
+			if c.onReconnect != nil {
+				c.onReconnect(attempt, err)
+			}
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+
+		if c.onReconnect != nil {
+			c.onReconnect(attempt, nil)
+		}
+		return
+	}
+}
+
+// jitter 返回[0, d/2)范围内的随机抖动，避免大量客户端同时重连
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)/2 + 1))
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CursorStore 订阅游标存储接口，用于进程重启后从上次处理位置恢复订阅，
+// 而不是每次都从调用方传入的FromBlock重新扫描。
+//
+// 实现方必须保证：Save只在对应区块范围的事件已经被确认处理之后才被调用
+// （HTTP订阅在committedChannel ack之后，WS订阅在OnEvents无错误返回之后），
+// 从而提供至少一次（at-least-once）的投递语义——进程崩溃重启后可能重复
+// 收到游标保存前的事件，但不会丢失事件。
+type CursorStore interface {
+	// Load 读取subscriptionID对应的游标；若从未保存过，返回fromBlock=0, err=nil
+	Load(subscriptionID string) (fromBlock int, err error)
+	// Save 保存subscriptionID对应的游标，completed表示该区块是否已扫描完成
+	Save(subscriptionID string, block int, completed bool) error
+}
+
+// MemoryCursorStore 基于内存的游标存储，适用于测试或单进程内的短生命周期订阅
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+// NewMemoryCursorStore 创建一个内存游标存储
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]int)}
+}
+
+func (s *MemoryCursorStore) Load(subscriptionID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[subscriptionID], nil
+}
+
+func (s *MemoryCursorStore) Save(subscriptionID string, block int, completed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[subscriptionID] = block
+	return nil
+}
+
+// fileCursorRecord 单个订阅在磁盘上的游标记录
+type fileCursorRecord struct {
+	Block     int  `json:"block"`
+	Completed bool `json:"completed"`
+}
+
+// FileCursorStore 基于本地JSON文件的游标存储，每次Save都会先写临时文件再
+// fsync、rename覆盖原文件，保证进程崩溃/掉电后磁盘上的游标文件要么是上一次
+// 完整的内容，要么是这一次完整的内容，不会出现截断或损坏的中间状态。
+type FileCursorStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCursorStore 创建一个基于path路径JSON文件的游标存储
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+func (s *FileCursorStore) readRecords() (map[string]fileCursorRecord, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]fileCursorRecord), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cursor file failed: %w", err)
+	}
+	if len(data) == 0 {
+		return make(map[string]fileCursorRecord), nil
+	}
+	records := make(map[string]fileCursorRecord)
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("unmarshal cursor file failed: %w", err)
+	}
+	return records, nil
+}
+
+func (s *FileCursorStore) Load(subscriptionID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.readRecords()
+	if err != nil {
+		return 0, err
+	}
+	return records[subscriptionID].Block, nil
+}
+
+func (s *FileCursorStore) Save(subscriptionID string, block int, completed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.readRecords()
+	if err != nil {
+		return err
+	}
+	records[subscriptionID] = fileCursorRecord{Block: block, Completed: completed}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshal cursor file failed: %w", err)
+	}
+
+	// 先写临时文件并fsync，再rename覆盖原文件：rename在同一文件系统内是原子的，
+	// 避免crash/掉电发生在truncate之后、写入完成之前导致游标文件损坏
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp cursor file failed: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	// os.CreateTemp创建的文件权限是0600，显式改回0644，保持与旧的
+	// os.OpenFile(..., 0644)行为一致
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("chmod cursor file failed: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write cursor file failed: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("sync cursor file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close cursor file failed: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("rename cursor file failed: %w", err)
+	}
+	return nil
+}
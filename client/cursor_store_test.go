@@ -0,0 +1,74 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryCursorStoreRoundTrip(t *testing.T) {
+	s := NewMemoryCursorStore()
+
+	block, err := s.Load("sub-1")
+	if err != nil || block != 0 {
+		t.Fatalf("expected (0, nil) for an unseen subscription, got (%d, %v)", block, err)
+	}
+
+	if err := s.Save("sub-1", 42, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	block, err = s.Load("sub-1")
+	if err != nil || block != 42 {
+		t.Fatalf("expected (42, nil) after Save, got (%d, %v)", block, err)
+	}
+}
+
+func TestFileCursorStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	s := NewFileCursorStore(path)
+
+	block, err := s.Load("sub-1")
+	if err != nil || block != 0 {
+		t.Fatalf("expected (0, nil) for an unseen subscription, got (%d, %v)", block, err)
+	}
+
+	if err := s.Save("sub-1", 100, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.Save("sub-2", 7, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	block, err = s.Load("sub-1")
+	if err != nil || block != 100 {
+		t.Fatalf("expected (100, nil), got (%d, %v)", block, err)
+	}
+	block, err = s.Load("sub-2")
+	if err != nil || block != 7 {
+		t.Fatalf("expected (7, nil), got (%d, %v)", block, err)
+	}
+
+	// 重新打开同一路径，模拟进程重启后读到的是上一次已落盘的完整游标
+	reopened := NewFileCursorStore(path)
+	block, err = reopened.Load("sub-1")
+	if err != nil || block != 100 {
+		t.Fatalf("expected persisted cursor to survive reopen, got (%d, %v)", block, err)
+	}
+}
+
+func TestFileCursorStoreSaveLeavesNoTempFilesBehind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cursor.json")
+	s := NewFileCursorStore(path)
+
+	if err := s.Save("sub-1", 1, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	entries, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatalf("glob failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0] != path {
+		t.Fatalf("expected only the cursor file to remain, got %v", entries)
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchEventShardsOrdersResultsByShardIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req HttpEventsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		events := []*Event{{BlockNumber: uint64(req.FromBlock)}}
+		resp := PageResponse{
+			Code: 200,
+			Data: &Page{Page: 1, Size: len(events), Total: len(events), Data: events},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := &EventsClient{
+		BaseURL:     server.URL,
+		HttpClient:  http.DefaultClient,
+		BlockSize:   1,
+		EventSize:   10,
+		Concurrency: 4,
+	}
+
+	nextFromBlock, results, err := c.fetchEventShards(context.Background(), &FlowEventsRequest{}, 0, 7)
+	if err != nil {
+		t.Fatalf("fetchEventShards failed: %v", err)
+	}
+	if nextFromBlock != 8 {
+		t.Fatalf("expected nextFromBlock=8, got %d", nextFromBlock)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 shards, got %d", len(results))
+	}
+	// worker完成顺序不固定，但重排序缓冲区必须按分片下标（即区块号单调递增）返回
+	for i, eventData := range results {
+		want := uint64(i * 2)
+		if len(eventData.Events) != 1 || eventData.Events[0].BlockNumber != want {
+			t.Fatalf("shard %d out of order: want fromBlock %d, got %+v", i, want, eventData.Events)
+		}
+	}
+}
+
+func TestFetchEventShardsPropagatesFirstError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := PageResponse{Code: 400, Message: "bad request"}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := &EventsClient{
+		BaseURL:     server.URL,
+		HttpClient:  http.DefaultClient,
+		BlockSize:   1,
+		EventSize:   10,
+		Concurrency: 2,
+	}
+
+	if _, _, err := c.fetchEventShards(context.Background(), &FlowEventsRequest{}, 0, 3); err == nil {
+		t.Fatal("expected fetchEventShards to propagate the shard error")
+	}
+}
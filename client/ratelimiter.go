@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter 基于令牌桶算法的限流器，用于控制对索引服务的请求速率
+type RateLimiter struct {
+	mu             sync.Mutex
+	tokens         float64
+	burst          float64
+	requestsPerSec float64
+	lastRefill     time.Time
+}
+
+// NewRateLimiter 创建一个限流器，requestsPerSecond为每秒允许的请求数，burst为令牌桶容量（允许的突发请求数）
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &RateLimiter{
+		tokens:         float64(burst),
+		burst:          float64(burst),
+		requestsPerSec: requestsPerSecond,
+		lastRefill:     time.Now(),
+	}
+}
+
+// refill 按经过的时间补充令牌，调用方需持有r.mu
+func (r *RateLimiter) refill() {
+	now := time.Now()
+	elapsed := now.Sub(r.lastRefill).Seconds()
+	r.lastRefill = now
+	if elapsed <= 0 {
+		return
+	}
+	r.tokens += elapsed * r.requestsPerSec
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+}
+
+// Allow 尝试立即获取一个令牌，不阻塞
+func (r *RateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill()
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// Wait 阻塞直到获取到一个令牌，或ctx被取消/超时
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		r.refill()
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		missing := 1 - r.tokens
+		wait := time.Duration(missing / r.requestsPerSec * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
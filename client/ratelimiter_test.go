@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	rl := NewRateLimiter(1000, 2)
+	if !rl.Allow() {
+		t.Fatal("expected first Allow to succeed")
+	}
+	if !rl.Allow() {
+		t.Fatal("expected second Allow to succeed (burst=2)")
+	}
+	if rl.Allow() {
+		t.Fatal("expected third Allow to fail once tokens are exhausted")
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	rl := NewRateLimiter(1, 1)
+	rl.Allow() // 耗尽唯一的令牌
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := rl.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to return an error once ctx is canceled before a token refills")
+	}
+}
+
+func TestRateLimiterWaitUnblocksOnRefill(t *testing.T) {
+	rl := NewRateLimiter(100, 1)
+	rl.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := rl.Wait(ctx); err != nil {
+		t.Fatalf("expected Wait to succeed once tokens refill, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStreamClientTrackLastSeenBlockAdvancesOnHigherBlock(t *testing.T) {
+	sc := NewStreamClient(StreamConfig{})
+	sc.subscriptions["sub-a"] = &streamSubscription{request: WSRequest{FromBlock: 10}, lastSeenBlock: 9}
+
+	sc.trackLastSeenBlock("sub-a", []Event{{BlockNumber: 12}, {BlockNumber: 11}})
+
+	sc.mu.Lock()
+	got := sc.subscriptions["sub-a"].lastSeenBlock
+	sc.mu.Unlock()
+	if got != 12 {
+		t.Fatalf("expected lastSeenBlock=12 after tracking out-of-order events, got %d", got)
+	}
+}
+
+func TestStreamClientTrackLastSeenBlockCreatesUnknownSubscription(t *testing.T) {
+	sc := NewStreamClient(StreamConfig{})
+
+	sc.trackLastSeenBlock("sub-new", []Event{{BlockNumber: 5}})
+
+	sc.mu.Lock()
+	sub, ok := sc.subscriptions["sub-new"]
+	sc.mu.Unlock()
+	if !ok || sub.lastSeenBlock != 5 {
+		t.Fatalf("expected sub-new tracked at block 5, got ok=%v sub=%+v", ok, sub)
+	}
+}
+
+// TestStreamClientDispatchEventsRoutesBySubscription 验证单连接多路复用场景下，
+// dispatchEvents会把事件按subscriptionID路由给OnEvents，并且只推进该订阅自己的游标
+func TestStreamClientDispatchEventsRoutesBySubscription(t *testing.T) {
+	store := NewMemoryCursorStore()
+	sc := NewStreamClient(StreamConfig{})
+	sc.subscriptions["sub-a"] = &streamSubscription{lastSeenBlock: 20}
+	sc.subscriptions["sub-b"] = &streamSubscription{lastSeenBlock: 30}
+
+	var gotSubID string
+	config := StreamConfig{
+		CursorStore: store,
+		OnEvents: func(events []Event, page, total int, subscriptionID string) error {
+			gotSubID = subscriptionID
+			return nil
+		},
+	}
+
+	sc.dispatchEvents(config, "sub-a", []Event{{BlockNumber: 20}}, 1, 1)
+
+	if gotSubID != "sub-a" {
+		t.Fatalf("expected OnEvents to receive subscriptionID sub-a, got %q", gotSubID)
+	}
+	if block, err := store.Load("sub-a"); err != nil || block != 20 {
+		t.Fatalf("expected cursor saved for sub-a at block 20, got (%d, %v)", block, err)
+	}
+	if block, err := store.Load("sub-b"); err != nil || block != 0 {
+		t.Fatalf("expected sub-b's cursor untouched by sub-a's dispatch, got (%d, %v)", block, err)
+	}
+}
+
+// TestStreamClientDispatchEventsDoesNotAdvanceLastSeenBlockOnFailure 验证OnEvents
+// 返回错误时，lastSeenBlock不会被推进：这样如果此时发生重连，OnReconnect会从旧的
+// lastSeenBlock+1续传，重新投递这批未处理成功的事件，而不是把它们当成已送达丢弃
+func TestStreamClientDispatchEventsDoesNotAdvanceLastSeenBlockOnFailure(t *testing.T) {
+	sc := NewStreamClient(StreamConfig{})
+	sc.subscriptions["sub-a"] = &streamSubscription{lastSeenBlock: 10}
+
+	config := StreamConfig{
+		OnEvents: func(events []Event, page, total int, subscriptionID string) error {
+			return errors.New("boom")
+		},
+	}
+
+	sc.dispatchEvents(config, "sub-a", []Event{{BlockNumber: 20}}, 1, 1)
+
+	sc.mu.Lock()
+	got := sc.subscriptions["sub-a"].lastSeenBlock
+	sc.mu.Unlock()
+	if got != 10 {
+		t.Fatalf("expected lastSeenBlock to stay at 10 when OnEvents fails, got %d", got)
+	}
+}
+
+// TestStreamClientDispatchEventsAdvancesLastSeenBlockOnSuccess 验证OnEvents成功
+// 返回后，lastSeenBlock才会被推进到这批事件的最大区块号
+func TestStreamClientDispatchEventsAdvancesLastSeenBlockOnSuccess(t *testing.T) {
+	sc := NewStreamClient(StreamConfig{})
+	sc.subscriptions["sub-a"] = &streamSubscription{lastSeenBlock: 10}
+
+	config := StreamConfig{
+		OnEvents: func(events []Event, page, total int, subscriptionID string) error {
+			return nil
+		},
+	}
+
+	sc.dispatchEvents(config, "sub-a", []Event{{BlockNumber: 20}}, 1, 1)
+
+	sc.mu.Lock()
+	got := sc.subscriptions["sub-a"].lastSeenBlock
+	sc.mu.Unlock()
+	if got != 20 {
+		t.Fatalf("expected lastSeenBlock to advance to 20 once OnEvents succeeds, got %d", got)
+	}
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWSServer是一个基于httptest.Server的最小WebSocket回显服务端，
+// 用于驱动WSClient做connect/send/receive以及断线重连的集成测试
+type testWSServer struct {
+	httpServer *httptest.Server
+	wsURL      string
+
+	mu       sync.Mutex
+	lastConn *websocket.Conn
+}
+
+func newTestWSServer(t *testing.T, onMessage func(msg []byte) []byte) *testWSServer {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	s := &testWSServer{}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.lastConn = conn
+		s.mu.Unlock()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if onMessage == nil {
+				continue
+			}
+			if resp := onMessage(message); resp != nil {
+				_ = conn.WriteMessage(websocket.TextMessage, resp)
+			}
+		}
+	}))
+	s.wsURL = "ws" + strings.TrimPrefix(s.httpServer.URL, "http")
+	return s
+}
+
+func (s *testWSServer) Close() {
+	s.httpServer.Close()
+}
+
+// forceCloseActiveConn模拟服务端主动断开连接，用于触发客户端重连
+func (s *testWSServer) forceCloseActiveConn() {
+	s.mu.Lock()
+	conn := s.lastConn
+	s.mu.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+func TestWSClientConnectSendReceive(t *testing.T) {
+	server := newTestWSServer(t, func(msg []byte) []byte {
+		return []byte(`{"type":"events","message":"ok"}`)
+	})
+	defer server.Close()
+
+	received := make(chan *WSMessage, 1)
+	c := NewWSClient(WSConfig{
+		URL: server.wsURL,
+		OnMessage: func(msg *WSMessage) {
+			received <- msg
+		},
+	})
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer c.Close()
+
+	if !c.IsConnected() {
+		t.Fatal("expected IsConnected() to be true right after Connect")
+	}
+
+	if err := c.SendRequest(WSRequest{FromBlock: 1}); err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.Type != "events" || msg.Message != "ok" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for OnMessage")
+	}
+}
+
+func TestWSClientCloseStopsReconnect(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	defer server.Close()
+
+	var reconnectCalls int32
+	c := NewWSClient(WSConfig{
+		URL: server.wsURL,
+		OnReconnect: func(attempt int, err error) {
+			atomic.AddInt32(&reconnectCalls, 1)
+		},
+	})
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if c.IsConnected() {
+		t.Fatal("expected IsConnected() to be false after Close")
+	}
+
+	// 给任何可能的错误触发的重连一点时间，确认Close()之后不会再尝试重连
+	time.Sleep(200 * time.Millisecond)
+	if got := atomic.LoadInt32(&reconnectCalls); got != 0 {
+		t.Fatalf("expected no reconnect attempts after Close, got %d", got)
+	}
+}
+
+func TestWSClientHeartbeatTimeoutReportsError(t *testing.T) {
+	server := newTestWSServer(t, nil) // 不回复任何消息，也不会有服务端发起的控制帧
+	defer server.Close()
+
+	errs := make(chan error, 1)
+	c := NewWSClient(WSConfig{
+		URL:        server.wsURL,
+		PongWait:   80 * time.Millisecond,
+		PingPeriod: 10 * time.Second, // 远大于PongWait，确保客户端自己的ping不会先到
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer c.Close()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrHeartbeatTimeout) {
+			t.Fatalf("expected ErrHeartbeatTimeout, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for heartbeat-timeout error")
+	}
+}
+
+// TestWSClientReconnectClosesPreviousGenerationSession验证：服务端断线触发客户端
+// 重连成功后，上一代连接的session.done已经被关闭（即它的writePump/readPump已经
+// 退出），不会有一个仍然存活、最终把Ping写到新连接上的旧writePump
+func TestWSClientReconnectClosesPreviousGenerationSession(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	defer server.Close()
+
+	reconnected := make(chan struct{}, 1)
+	c := NewWSClient(WSConfig{
+		URL:        server.wsURL,
+		PingPeriod: 20 * time.Millisecond,
+		PongWait:   500 * time.Millisecond,
+		OnReconnect: func(attempt int, err error) {
+			if err == nil {
+				select {
+				case reconnected <- struct{}{}:
+				default:
+				}
+			}
+		},
+	})
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer c.Close()
+
+	c.mu.RLock()
+	firstSession := c.session
+	c.mu.RUnlock()
+	if firstSession == nil {
+		t.Fatal("expected a session to be set right after Connect")
+	}
+
+	server.forceCloseActiveConn()
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect to succeed")
+	}
+
+	select {
+	case <-firstSession.done:
+	default:
+		t.Fatal("expected previous generation's session.done to be closed once superseded, but it's still open -- the old writePump could leak and write to the new connection")
+	}
+
+	c.mu.RLock()
+	newSession := c.session
+	c.mu.RUnlock()
+	if newSession == firstSession {
+		t.Fatal("expected a new connSession to be installed after reconnect")
+	}
+}
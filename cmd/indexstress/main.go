@@ -0,0 +1,52 @@
+// Command indexstress 对索引服务的HTTP+WS接口施加负载，
+// 输出行分隔的单次请求JSON和最终汇总报告
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MeinAJ/indexing-sdk/stress"
+)
+
+func main() {
+	baseURL := flag.String("base-url", "http://127.0.0.1:8080", "索引HTTP服务地址")
+	wsURL := flag.String("ws-url", "ws://127.0.0.1:8080/api/v1/event/ws/stream", "索引WebSocket服务地址")
+	mode := flag.String("mode", "http", "压测模式: http | ws | mixed")
+	concurrency := flag.Int("c", 10, "并发数")
+	requests := flag.Int("n", 0, "每个worker的请求数，<=0表示改用-d控制时长")
+	duration := flag.Duration("d", 30*time.Second, "压测时长，-n<=0时生效")
+	rampUp := flag.Duration("ramp-up", 0, "爬坡时长")
+	fromBlock := flag.Int("from-block", 0, "起始区块号")
+	verify := flag.String("verify", "", "结果校验格式，目前仅支持json")
+	flag.Parse()
+
+	cfg := stress.Config{
+		BaseURL:           *baseURL,
+		WSURL:             *wsURL,
+		Mode:              stress.Mode(*mode),
+		Concurrency:       *concurrency,
+		RequestsPerWorker: *requests,
+		Duration:          *duration,
+		RampUp:            *rampUp,
+		FromBlock:         *fromBlock,
+		Verify:            *verify,
+		Output:            os.Stdout,
+	}
+
+	report, err := stress.Run(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stress run failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "marshal report failed: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
@@ -1,6 +1,7 @@
 package demo
 
 import (
+	"context"
 	"fmt"
 	"github.com/MeinAJ/indexing-sdk/client"
 	"time"
@@ -23,10 +24,10 @@ func TestHttp() {
 		EventNames: []string{"Transfer"},
 	}
 
-	var dataChannel = make(chan client.EventData)
+	var dataChannel = make(chan *client.EventData)
 	var committedChannel = make(chan interface{})
 
-	err := eventsClient.SubscribeEvents(req, dataChannel, committedChannel)
+	err := eventsClient.SubscribeEvents(context.Background(), req, dataChannel, committedChannel)
 	if err != nil {
 		fmt.Println(err)
 		return
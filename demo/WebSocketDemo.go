@@ -13,9 +13,10 @@ func TestWebsocket() {
 			FromBlock: 9760967,
 			ToBlock:   9761177,
 		},
-		OnEvents: func(events []client.Event, page, total int) {
+		OnEvents: func(events []client.Event, page, total int, subscriptionID string) error {
 			// 处理事件
 			fmt.Println(json.Marshal(events))
+			return nil
 		}}
 	// 创建流式客户端
 	streamClient := client.NewStreamClient(config)
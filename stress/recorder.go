@@ -0,0 +1,130 @@
+package stress
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RequestResult 单次请求的压测记录，以行分隔JSON的形式写入Config.Output
+type RequestResult struct {
+	Endpoint  string  `json:"endpoint"`
+	WorkerID  int     `json:"workerId"`
+	Success   bool    `json:"success"`
+	ErrorCode string  `json:"errorCode,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// recorder 线程安全地收集每个端点的延迟样本和错误直方图，
+// 并将每条结果实时写出为行分隔JSON
+type recorder struct {
+	mu             sync.Mutex
+	output         io.Writer
+	counts         map[string]int
+	latencies      map[string][]time.Duration
+	errorHistogram map[string]map[string]int
+	reconnectCount int
+}
+
+func newRecorder(output io.Writer) *recorder {
+	return &recorder{
+		output:         output,
+		counts:         make(map[string]int),
+		latencies:      make(map[string][]time.Duration),
+		errorHistogram: make(map[string]map[string]int),
+	}
+}
+
+// record 记录一次请求结果：累加统计数据，并写出一行JSON
+func (r *recorder) record(endpoint string, workerID int, latency time.Duration, err error) {
+	result := RequestResult{
+		Endpoint:  endpoint,
+		WorkerID:  workerID,
+		Success:   err == nil,
+		LatencyMs: float64(latency) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.ErrorCode = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts[endpoint]++
+	r.latencies[endpoint] = append(r.latencies[endpoint], latency)
+	if err != nil {
+		hist, ok := r.errorHistogram[endpoint]
+		if !ok {
+			hist = make(map[string]int)
+			r.errorHistogram[endpoint] = hist
+		}
+		hist[result.ErrorCode]++
+	}
+
+	if data, marshalErr := json.Marshal(result); marshalErr == nil {
+		_, _ = r.output.Write(append(data, '\n'))
+	}
+}
+
+// incrReconnect 累加WS重连成功次数
+func (r *recorder) incrReconnect() {
+	r.mu.Lock()
+	r.reconnectCount++
+	r.mu.Unlock()
+}
+
+// buildReport 汇总出每个端点的p50/p95/p99延迟、吞吐量和错误直方图
+func (r *recorder) buildReport(mode Mode, startedAt time.Time, duration time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoints := make(map[string]*EndpointReport, len(r.counts))
+	for endpoint, latencies := range r.latencies {
+		sorted := append([]time.Duration(nil), latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		errCount := 0
+		for _, count := range r.errorHistogram[endpoint] {
+			errCount += count
+		}
+
+		throughput := 0.0
+		if duration > 0 {
+			throughput = float64(r.counts[endpoint]) / duration.Seconds()
+		}
+
+		endpoints[endpoint] = &EndpointReport{
+			Endpoint:       endpoint,
+			Count:          r.counts[endpoint],
+			ErrorCount:     errCount,
+			P50Ms:          msOf(percentile(sorted, 0.50)),
+			P95Ms:          msOf(percentile(sorted, 0.95)),
+			P99Ms:          msOf(percentile(sorted, 0.99)),
+			ThroughputQPS:  throughput,
+			ErrorHistogram: r.errorHistogram[endpoint],
+		}
+	}
+
+	return &Report{
+		Mode:           mode,
+		StartedAt:      startedAt,
+		Duration:       duration,
+		Endpoints:      endpoints,
+		ReconnectCount: r.reconnectCount,
+	}
+}
+
+// percentile 返回已排序切片中p分位（0~1）对应的值
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+func msOf(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,207 @@
+// Package stress 复用client.EventsClient和client.WSClient，对索引服务的
+// HTTP+WS接口施加可配置的负载，并汇总每个端点的延迟分位数、吞吐量、
+// 错误码直方图和WS重连次数。
+package stress
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/MeinAJ/indexing-sdk/client"
+)
+
+// Mode 压测模式
+type Mode string
+
+const (
+	ModeHTTP  Mode = "http"  // 仅并发轮询HTTP接口
+	ModeWS    Mode = "ws"    // 仅并发建立WS订阅
+	ModeMixed Mode = "mixed" // worker按奇偶拆分为HTTP/WS两组
+)
+
+// Config 压测配置
+type Config struct {
+	BaseURL           string        // 索引HTTP服务地址
+	WSURL             string        // 索引WebSocket服务地址
+	Mode              Mode          // 压测模式，默认http
+	Concurrency       int           // 并发worker数(-c)，默认1
+	RequestsPerWorker int           // 每个worker发送的请求数(-n)，<=0表示改用Duration控制
+	Duration          time.Duration // 压测时长(-d)，RequestsPerWorker<=0时生效
+	RampUp            time.Duration // 爬坡时长，worker在此时间内均匀错峰启动
+	FromBlock         int           // HTTP/WS请求的起始区块号
+	Verify            string        // 结果校验格式，目前仅支持"json"：对每条收到的事件做必填字段和区块号单调性校验，失败计入对应端点的":verify"子统计
+	Output            io.Writer     // 逐条请求结果的行分隔JSON输出，默认为io.Discard
+}
+
+// EndpointReport 单个端点的压测汇总结果
+type EndpointReport struct {
+	Endpoint       string         `json:"endpoint"`
+	Count          int            `json:"count"`
+	ErrorCount     int            `json:"errorCount"`
+	P50Ms          float64        `json:"p50Ms"`
+	P95Ms          float64        `json:"p95Ms"`
+	P99Ms          float64        `json:"p99Ms"`
+	ThroughputQPS  float64        `json:"throughputQps"`
+	ErrorHistogram map[string]int `json:"errorHistogram,omitempty"`
+}
+
+// Report 一次压测的完整汇总结果
+type Report struct {
+	Mode           Mode                       `json:"mode"`
+	StartedAt      time.Time                  `json:"startedAt"`
+	Duration       time.Duration              `json:"duration"`
+	Endpoints      map[string]*EndpointReport `json:"endpoints"`
+	ReconnectCount int                        `json:"reconnectCount"` // WS订阅的累计重连次数
+}
+
+// Run 按cfg施加负载并返回汇总报告；Config.Output若设置，会同步写入
+// 行分隔的JSON请求结果，便于实时管道消费
+func Run(cfg Config) (*Report, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeHTTP
+	}
+	if cfg.Output == nil {
+		cfg.Output = io.Discard
+	}
+
+	ctx := context.Background()
+	if cfg.Duration > 0 && cfg.RequestsPerWorker <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	rec := newRecorder(cfg.Output)
+	rampStep := time.Duration(0)
+	if cfg.RampUp > 0 {
+		rampStep = cfg.RampUp / time.Duration(cfg.Concurrency)
+	}
+
+	startedAt := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			select {
+			case <-time.After(rampStep * time.Duration(workerID)):
+			case <-ctx.Done():
+				return
+			}
+			runWorker(ctx, cfg, workerID, rec)
+		}(i)
+	}
+	wg.Wait()
+
+	return rec.buildReport(cfg.Mode, startedAt, time.Since(startedAt)), nil
+}
+
+// runWorker 按cfg.Mode将一个worker派发为HTTP poller或WS subscriber
+func runWorker(ctx context.Context, cfg Config, workerID int, rec *recorder) {
+	switch cfg.Mode {
+	case ModeWS:
+		runWSWorker(ctx, cfg, workerID, rec)
+	case ModeMixed:
+		if workerID%2 == 0 {
+			runHTTPWorker(ctx, cfg, workerID, rec)
+		} else {
+			runWSWorker(ctx, cfg, workerID, rec)
+		}
+	default:
+		runHTTPWorker(ctx, cfg, workerID, rec)
+	}
+}
+
+// runHTTPWorker 循环调用GetLatestBlockNumber/GetEvents，直到ctx被取消或
+// 达到RequestsPerWorker次数
+func runHTTPWorker(ctx context.Context, cfg Config, workerID int, rec *recorder) {
+	eventsClient := client.NewEventsClient(&client.Config{BaseURL: cfg.BaseURL})
+	fromBlock := cfg.FromBlock
+
+	for i := 0; cfg.RequestsPerWorker <= 0 || i < cfg.RequestsPerWorker; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		start := time.Now()
+		latest, err := eventsClient.GetLatestBlockNumber(ctx)
+		rec.record("http:latestBlockNumber", workerID, time.Since(start), err)
+		if err != nil {
+			continue
+		}
+
+		start = time.Now()
+		response, err := eventsClient.GetEvents(ctx, &client.HttpEventsRequest{
+			FromBlock:  fromBlock,
+			ToBlock:    fromBlock + eventsClient.BlockSize,
+			PageNumber: 1,
+			PageSize:   eventsClient.EventSize,
+		})
+		rec.record("http:getEvents", workerID, time.Since(start), err)
+		if err != nil {
+			continue
+		}
+		if cfg.Verify == "json" && response.Data != nil {
+			rec.record("http:getEvents:verify", workerID, 0, verifyJSON(response.Data.Data))
+		}
+
+		nextFromBlock := fromBlock + eventsClient.BlockSize + 1
+		if nextFromBlock > int(latest) {
+			nextFromBlock = cfg.FromBlock
+		}
+		fromBlock = nextFromBlock
+		_ = response
+	}
+}
+
+// runWSWorker 建立一条WS订阅并持续接收消息，直到ctx被取消；OnReconnect
+// 成功时计入rec的重连计数
+func runWSWorker(ctx context.Context, cfg Config, workerID int, rec *recorder) {
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	markDone := func() { closeOnce.Do(func() { close(done) }) }
+
+	wsClient := client.NewWSClient(client.WSConfig{
+		URL: cfg.WSURL,
+		OnMessage: func(msg *client.WSMessage) {
+			rec.record("ws:message", workerID, 0, nil)
+			if cfg.Verify == "json" {
+				rec.record("ws:message:verify", workerID, 0, verifyWSMessage(msg))
+			}
+		},
+		OnError: func(err error) {
+			rec.record("ws:error", workerID, 0, err)
+		},
+		OnReconnect: func(attempt int, err error) {
+			if err == nil {
+				rec.incrReconnect()
+			}
+		},
+		OnClose: markDone,
+	})
+
+	start := time.Now()
+	err := wsClient.Connect()
+	rec.record("ws:connect", workerID, time.Since(start), err)
+	if err != nil {
+		return
+	}
+	defer func() { _ = wsClient.Close() }()
+
+	if err := wsClient.SendRequest(client.WSRequest{FromBlock: cfg.FromBlock}); err != nil {
+		rec.record("ws:subscribe", workerID, 0, err)
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+}
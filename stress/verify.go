@@ -0,0 +1,52 @@
+package stress
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MeinAJ/indexing-sdk/client"
+)
+
+// verifyJSON 按cfg.Verify="json"校验格式检查一批事件的基本结构是否合法：
+// 必填字段（EventUniqueHash/EventName）非空，且区块号在批次内单调不减。
+// 目前"json"是唯一支持的校验格式
+func verifyJSON(events []*client.Event) error {
+	var prevBlock uint64
+	for i, event := range events {
+		if event == nil {
+			return fmt.Errorf("event[%d] is nil", i)
+		}
+		if event.EventUniqueHash == "" {
+			return fmt.Errorf("event[%d] missing eventUniqueHash", i)
+		}
+		if event.EventName == "" {
+			return fmt.Errorf("event[%d] missing eventName", i)
+		}
+		if i > 0 && event.BlockNumber < prevBlock {
+			return fmt.Errorf("event[%d] blockNumber %d out of order after %d", i, event.BlockNumber, prevBlock)
+		}
+		prevBlock = event.BlockNumber
+	}
+	return nil
+}
+
+// verifyWSMessage 对一条"events"/"new_event"类型的WS消息做与verifyJSON相同的校验；
+// 其他类型的消息（heartbeat/info/error）无事件数据，不做校验
+func verifyWSMessage(msg *client.WSMessage) error {
+	switch msg.Type {
+	case "events":
+		var events []*client.Event
+		if err := json.Unmarshal(msg.Data, &events); err != nil {
+			return fmt.Errorf("unmarshal events payload failed: %w", err)
+		}
+		return verifyJSON(events)
+	case "new_event":
+		var event client.Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			return fmt.Errorf("unmarshal new_event payload failed: %w", err)
+		}
+		return verifyJSON([]*client.Event{&event})
+	default:
+		return nil
+	}
+}